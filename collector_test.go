@@ -0,0 +1,222 @@
+// Copyright (c) 2015 by Christoph Hack <christoph@tux21b.org>
+// All rights reserved. Distributed under the Simplified BSD License.
+
+package main
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capturedMetric is one reportMetric call recorded by captureMetrics.
+type capturedMetric struct {
+	alias, key, pxname, svname, value string
+}
+
+// captureMetrics runs fn with reportMetric swapped out for a recording
+// stand-in, restoring the previous reportMetric (collectdEmit in
+// production, or a promSink's report method mid-scrape) afterwards.
+func captureMetrics(t *testing.T, fn func()) []capturedMetric {
+	t.Helper()
+	orig := reportMetric
+	defer func() { reportMetric = orig }()
+
+	var got []capturedMetric
+	reportMetric = func(alias, key, pxname, svname, value string, now time.Time, interval time.Duration) {
+		got = append(got, capturedMetric{alias, key, pxname, svname, value})
+	}
+	fn()
+	return got
+}
+
+func findMetric(metrics []capturedMetric, key string) (capturedMetric, bool) {
+	for _, m := range metrics {
+		if m.key == key {
+			return m, true
+		}
+	}
+	return capturedMetric{}, false
+}
+
+// newScriptedHAProxy starts a fake haproxy admin socket in prompt mode on
+// a loopback TCP port, serving a canned reply for each known command, for
+// use by tests that exercise the session/run protocol framing and the
+// collectors layered on top of it. It returns an addr suitable for the
+// -instance/-socket flags, e.g. "tcp:127.0.0.1:1234".
+func newScriptedHAProxy(t *testing.T, replies map[string]string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveScriptedConn(conn, replies)
+		}
+	}()
+	return "tcp:" + ln.Addr().String()
+}
+
+// serveScriptedConn implements just enough of haproxy's prompt mode to
+// drive session.connect/run: it echoes the "> " prompt after the initial
+// "prompt" command and after every subsequent command, replying with
+// whatever was scripted for that command (or nothing, if unscripted).
+func serveScriptedConn(conn net.Conn, replies map[string]string) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	line, err := rd.ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "prompt" {
+		return
+	}
+	if _, err := conn.Write([]byte("> ")); err != nil {
+		return
+	}
+	for {
+		cmd, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if reply, ok := replies[strings.TrimSpace(cmd)]; ok {
+			if _, err := conn.Write([]byte(reply)); err != nil {
+				return
+			}
+		}
+		if _, err := conn.Write([]byte("> ")); err != nil {
+			return
+		}
+	}
+}
+
+// TestMain gives the session layer's dial/read deadlines (bound by the
+// package-level interval var, normally set in main from collectd's exec
+// environment or, in -listen mode, the -interval flag) a sane
+// value for every test in the package.
+func TestMain(m *testing.M) {
+	interval = 5 * time.Second
+	os.Exit(m.Run())
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		status string
+		want   int
+		ok     bool
+	}{
+		{"UP", 0, true},
+		{"UP 1/3", 0, true},
+		{"DOWN", 1, true},
+		{"down", 1, true},
+		{"OPEN", 5, true},
+		{"MAINT", 3, true},
+		{"bogus", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := statusCode(tt.status)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("statusCode(%q) = (%d, %v), want (%d, %v)", tt.status, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCollectInstanceServersStateDecodesHexAdminState(t *testing.T) {
+	addr := newScriptedHAProxy(t, map[string]string{
+		"show servers state": "1\n" +
+			"# be_id be_name srv_id srv_name srv_admin_state srv_op_state\n" +
+			"1 backend1 1 web1 20 2\n" +
+			"\n",
+	})
+	inst := instance{alias: "test", addr: addr}
+
+	metrics := captureMetrics(t, func() {
+		collectInstanceServersState(inst, time.Second)
+	})
+
+	m, ok := findMetric(metrics, "srv_admin_state")
+	if !ok {
+		t.Fatal("srv_admin_state metric not reported")
+	}
+	if m.value != "32" {
+		t.Errorf("srv_admin_state = %q, want %q (0x20 decoded as decimal)", m.value, "32")
+	}
+	if m.pxname != "backend1" || m.svname != "web1" {
+		t.Errorf("pxname/svname = %q/%q, want backend1/web1", m.pxname, m.svname)
+	}
+}
+
+func TestCollectInstancePools(t *testing.T) {
+	addr := newScriptedHAProxy(t, map[string]string{
+		"show pools": "Dumping pools usage\n" +
+			"  - Pool trash (16384 bytes) : 10 allocated (163840 bytes), 3 used, needs_allocation\n" +
+			"\n",
+	})
+	inst := instance{alias: "test", addr: addr}
+
+	metrics := captureMetrics(t, func() {
+		collectInstancePools(inst, time.Second)
+	})
+
+	allocated, ok := findMetric(metrics, "pool_allocated")
+	if !ok || allocated.value != "163840" || allocated.pxname != "trash" {
+		t.Errorf("pool_allocated = %+v, want value 163840 pxname trash", allocated)
+	}
+	used, ok := findMetric(metrics, "pool_used")
+	if !ok || used.value != "3" || used.pxname != "trash" {
+		t.Errorf("pool_used = %+v, want value 3 pxname trash", used)
+	}
+}
+
+func TestCollectInstanceResolvers(t *testing.T) {
+	addr := newScriptedHAProxy(t, map[string]string{
+		"show resolvers": "Resolvers section mydns\n" +
+			" nameserver dns1:\n" +
+			"  sent:        10\n" +
+			"Total:\n" +
+			"  sent:        10\n" +
+			"  valid:        8\n" +
+			"\n",
+	})
+	inst := instance{alias: "test", addr: addr}
+
+	metrics := captureMetrics(t, func() {
+		collectInstanceResolvers(inst, time.Second)
+	})
+
+	sent, ok := findMetric(metrics, "resolvers_sent")
+	if !ok || sent.value != "10" || sent.pxname != "mydns" {
+		t.Errorf("resolvers_sent = %+v, want value 10 pxname mydns", sent)
+	}
+	valid, ok := findMetric(metrics, "resolvers_valid")
+	if !ok || valid.value != "8" {
+		t.Errorf("resolvers_valid = %+v, want value 8", valid)
+	}
+}
+
+func TestCollectInstanceTable(t *testing.T) {
+	addr := newScriptedHAProxy(t, map[string]string{
+		"show table mytable": "# table: mytable, type: ip, size:1048576, used:42\n" +
+			"\n",
+	})
+	inst := instance{alias: "test", addr: addr}
+
+	metrics := captureMetrics(t, func() {
+		collectInstanceTable(inst, "mytable", time.Second)
+	})
+
+	size, ok := findMetric(metrics, "table_size")
+	if !ok || size.value != "1048576" || size.pxname != "mytable" {
+		t.Errorf("table_size = %+v, want value 1048576 pxname mytable", size)
+	}
+	used, ok := findMetric(metrics, "table_used")
+	if !ok || used.value != "42" {
+		t.Errorf("table_used = %+v, want value 42", used)
+	}
+}
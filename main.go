@@ -2,7 +2,8 @@
 // All rights reserved. Distributed under the Simplified BSD License.
 
 // Command collectd-haproxy implements a collectd exec plugin to collect
-// metrics from haproxy via an admin socket.
+// metrics from haproxy via an admin socket. Passing -listen switches it
+// to serving those same metrics as a Prometheus /metrics endpoint instead.
 //
 // Example configuration:
 //   LoadPlugin exec
@@ -12,6 +13,7 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/csv"
 	"flag"
@@ -20,7 +22,9 @@ import (
 	"io/ioutil"
 	"log"
 	"net"
+	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,18 +35,71 @@ import (
 )
 
 var (
-	hostname string
-	plugin   string
-	socket   string
-	interval time.Duration
+	hostname  string
+	plugin    string
+	instances instanceList
+	interval  time.Duration
 )
 
+// instance identifies a single haproxy admin socket to scrape, together
+// with the alias used to tell its metrics apart from other instances.
+type instance struct {
+	alias string
+	addr  string
+}
+
+// instanceList implements flag.Value so that -instance can be repeated on
+// the command line to scrape more than one haproxy process per interval.
+type instanceList []instance
+
+func (l *instanceList) String() string {
+	parts := make([]string, len(*l))
+	for i, inst := range *l {
+		parts[i] = inst.alias + "=" + inst.addr
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set parses a single "-instance name=addr" flag occurrence. addr may be
+// "unix:/path/to/socket" or "tcp:host:port"; a bare path is treated as a
+// unix socket for backwards compatibility.
+func (l *instanceList) Set(value string) error {
+	alias, addr := value, ""
+	if i := strings.IndexByte(value, '='); i >= 0 {
+		alias, addr = value[:i], value[i+1:]
+	}
+	if alias == "" || addr == "" {
+		return fmt.Errorf("invalid -instance %q, want name=addr", value)
+	}
+	*l = append(*l, instance{alias: alias, addr: addr})
+	return nil
+}
+
+// stringList implements flag.Value to allow -table to be repeated on the
+// command line, one sticky-table name per occurrence.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+var tables stringList
+
 func main() {
 	var (
-		flagSocket = flag.String("socket", "/var/run/haproxy/admin.sock", "haproxy admin socket")
-		flagPlugin = flag.String("plugin", "haproxy", "plugin name")
-		flagSilent = flag.Bool("silent", false, "silent mode")
+		flagSocket   = flag.String("socket", "/var/run/haproxy/admin.sock", "haproxy admin socket, used when no -instance is given")
+		flagPlugin   = flag.String("plugin", "haproxy", "plugin name")
+		flagSilent   = flag.Bool("silent", false, "silent mode")
+		flagListen   = flag.String("listen", "", "if set, serve /metrics in Prometheus text format on this address instead of running as a collectd exec plugin")
+		flagInterval = flag.Duration("interval", 10*time.Second, "admin socket dial/read deadline, only used in -listen mode; does not throttle scrapes, each /metrics request collects fresh")
 	)
+	flag.Var(&instances, "instance", "name=addr pair identifying a haproxy admin socket (may be repeated); addr is unix:/path or tcp:host:port")
+	flag.Var(&tables, "table", "sticky-table name to query via `show table <name>` (may be repeated)")
 	if flag.Parse(); flag.NArg() != 0 {
 		flag.Usage()
 		os.Exit(1)
@@ -52,23 +109,43 @@ func main() {
 		log.SetOutput(ioutil.Discard)
 	}
 
-	socket = *flagSocket
+	if len(instances) == 0 {
+		instances = instanceList{{alias: "", addr: *flagSocket}}
+	}
 	plugin = *flagPlugin
+
+	if *flagListen != "" {
+		interval = *flagInterval
+		http.HandleFunc("/metrics", metricsHandler)
+		log.Fatal(http.ListenAndServe(*flagListen, nil))
+		return
+	}
+
 	hostname = exec.Hostname()
 	interval = exec.Interval()
 
 	e := exec.NewExecutor()
 	e.VoidCallback(collectInfo, interval)
 	e.VoidCallback(collectStats, interval)
+	e.VoidCallback(collectServersState, interval)
+	e.VoidCallback(collectPools, interval)
+	e.VoidCallback(collectResolvers, interval)
+	e.VoidCallback(collectTables, interval)
 	e.Run()
 }
 
 func collectInfo(interval time.Duration) {
+	for _, inst := range instances {
+		collectInstanceInfo(inst, interval)
+	}
+}
+
+func collectInstanceInfo(inst instance, interval time.Duration) {
 	now := time.Now()
 	buf := newBuffer()
 	defer freeBuffer(buf)
 
-	if err := communicate("show info", buf); err != nil {
+	if err := communicate(inst.addr, "show info", buf); err != nil {
 		log.Println("communicate:", err)
 		return
 	}
@@ -86,16 +163,22 @@ func collectInfo(interval time.Duration) {
 		}
 		key := string(bytes.ToLower(bytes.TrimSpace(line[:i])))
 		value := string(bytes.TrimSpace(line[i+1:]))
-		reportMetric(key, "", "", value, now, interval)
+		reportMetric(inst.alias, key, "", "", value, now, interval)
 	}
 }
 
 func collectStats(interval time.Duration) {
+	for _, inst := range instances {
+		collectInstanceStats(inst, interval)
+	}
+}
+
+func collectInstanceStats(inst instance, interval time.Duration) {
 	now := time.Now()
 	buf := newBuffer()
 	defer freeBuffer(buf)
 
-	if err := communicate("show stat", buf); err != nil {
+	if err := communicate(inst.addr, "show stat", buf); err != nil {
 		log.Println("communicate:", err)
 		return
 	}
@@ -116,12 +199,320 @@ func collectStats(interval time.Duration) {
 		}
 		pxname, svname := record[0], record[1]
 		for i := 2; i < len(header) && i < len(record); i++ {
-			reportMetric(header[i], pxname, svname, record[i], now, interval)
+			value := record[i]
+			if strings.EqualFold(strings.TrimSpace(header[i]), "status") {
+				code, ok := statusCode(value)
+				if !ok {
+					continue
+				}
+				value = strconv.Itoa(code)
+			}
+			reportMetric(inst.alias, header[i], pxname, svname, value, now, interval)
 		}
 	}
 }
 
-func reportMetric(key, pxname, svname, value string, now time.Time, interval time.Duration) {
+// statusCodes maps the state strings reported in show stat's "status"
+// column to small integer codes so operators can graph and alert on
+// backend flapping. Transitional states carry a "N/M" check counter
+// suffix (e.g. "UP 1/3") which is stripped before the lookup. "OPEN" is
+// what frontends report instead of "UP", and is tracked too so a bad
+// reload taking a frontend down still shows up in this metric.
+var statusCodes = map[string]int{
+	"UP":    0,
+	"DOWN":  1,
+	"NOLB":  2,
+	"MAINT": 3,
+	"DRAIN": 4,
+	"OPEN":  5,
+}
+
+func statusCode(status string) (int, bool) {
+	if i := strings.IndexByte(status, ' '); i >= 0 {
+		status = status[:i]
+	}
+	code, ok := statusCodes[strings.ToUpper(strings.TrimSpace(status))]
+	return code, ok
+}
+
+// collectServersState issues "show servers state" against every instance,
+// which reports per-server admin/operational state, weight and check
+// status. Unlike "show stat" the output is not CSV: the first line is a
+// version marker and the second is a "#"-prefixed, space-separated column
+// header. The "srv_admin_state" column is a hex-encoded bitmask rather
+// than a plain number, so it is decoded before being reported.
+func collectServersState(interval time.Duration) {
+	for _, inst := range instances {
+		collectInstanceServersState(inst, interval)
+	}
+}
+
+func collectInstanceServersState(inst instance, interval time.Duration) {
+	now := time.Now()
+	buf := newBuffer()
+	defer freeBuffer(buf)
+
+	if err := communicate(inst.addr, "show servers state", buf); err != nil {
+		log.Println("communicate:", err)
+		return
+	}
+
+	var header []string
+	line := 0
+	for {
+		text, err := buf.ReadString('\n')
+		text = strings.TrimSpace(text)
+		line++
+		switch {
+		case line == 1:
+			// version marker, nothing to parse
+		case header == nil:
+			header = strings.Fields(strings.TrimPrefix(text, "#"))
+		case text != "":
+			fields := strings.Fields(text)
+			if len(fields) < 4 {
+				break
+			}
+			pxname, svname := fields[1], fields[3]
+			for i := 4; i < len(header) && i < len(fields); i++ {
+				value := fields[i]
+				if strings.EqualFold(header[i], "srv_admin_state") {
+					bits, err := strconv.ParseUint(value, 16, 64)
+					if err != nil {
+						continue
+					}
+					value = strconv.FormatUint(bits, 10)
+				}
+				reportMetric(inst.alias, header[i], pxname, svname, value, now, interval)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+}
+
+// poolRE matches a single line of "show pools" output, e.g.:
+//   - Pool trash (16384 bytes) : 10 allocated (163840 bytes), 3 used, ...
+var poolRE = regexp.MustCompile(`-\s*Pool\s+(\S+)\s+\(\d+ bytes\)\s*:\s*\d+ allocated \((\d+) bytes\),\s*(\d+) used`)
+
+// collectPools issues "show pools" against every instance and reports the
+// allocated size and in-use count of each internal memory pool as gauges.
+func collectPools(interval time.Duration) {
+	for _, inst := range instances {
+		collectInstancePools(inst, interval)
+	}
+}
+
+func collectInstancePools(inst instance, interval time.Duration) {
+	now := time.Now()
+	buf := newBuffer()
+	defer freeBuffer(buf)
+
+	if err := communicate(inst.addr, "show pools", buf); err != nil {
+		log.Println("communicate:", err)
+		return
+	}
+	for {
+		text, err := buf.ReadString('\n')
+		if m := poolRE.FindStringSubmatch(text); m != nil {
+			reportMetric(inst.alias, "pool_allocated", m[1], "", m[2], now, interval)
+			reportMetric(inst.alias, "pool_used", m[1], "", m[3], now, interval)
+		}
+		if err != nil {
+			break
+		}
+	}
+}
+
+// collectResolvers issues "show resolvers" against every instance and
+// reports the aggregate "Total:" counters of each resolvers section as
+// derives.
+func collectResolvers(interval time.Duration) {
+	for _, inst := range instances {
+		collectInstanceResolvers(inst, interval)
+	}
+}
+
+func collectInstanceResolvers(inst instance, interval time.Duration) {
+	now := time.Now()
+	buf := newBuffer()
+	defer freeBuffer(buf)
+
+	if err := communicate(inst.addr, "show resolvers", buf); err != nil {
+		log.Println("communicate:", err)
+		return
+	}
+
+	var resolver string
+	inTotal := false
+	for {
+		text, err := buf.ReadString('\n')
+		text = strings.TrimSpace(text)
+		switch {
+		case strings.HasPrefix(text, "Resolvers section "):
+			resolver = strings.TrimSpace(strings.TrimPrefix(text, "Resolvers section "))
+			inTotal = false
+		case text == "Total:":
+			inTotal = true
+		case text == "":
+			inTotal = false
+		case inTotal:
+			if i := strings.IndexByte(text, ':'); i > 0 {
+				key := "resolvers_" + strings.TrimSpace(text[:i])
+				value := strings.TrimSpace(text[i+1:])
+				reportMetric(inst.alias, key, resolver, "", value, now, interval)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+}
+
+// tableRE matches the header line of "show table <name>" output, e.g.:
+//   # table: mytable, type: ip, size:1048576, used:42
+var tableRE = regexp.MustCompile(`table:\s*(\S+),\s*type:\s*\S+,\s*size:\s*(\d+),\s*used:\s*(\d+)`)
+
+// collectTables issues "show table <name>" for every -table flag against
+// every instance and reports the sticky-table's size and current usage
+// as gauges. It is a no-op when no -table flags were given.
+func collectTables(interval time.Duration) {
+	for _, inst := range instances {
+		for _, table := range tables {
+			collectInstanceTable(inst, table, interval)
+		}
+	}
+}
+
+func collectInstanceTable(inst instance, table string, interval time.Duration) {
+	now := time.Now()
+	buf := newBuffer()
+	defer freeBuffer(buf)
+
+	if err := communicate(inst.addr, "show table "+table, buf); err != nil {
+		log.Println("communicate:", err)
+		return
+	}
+	line, err := buf.ReadString('\n')
+	if err != nil && line == "" {
+		return
+	}
+	m := tableRE.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+	reportMetric(inst.alias, "table_size", m[1], "", m[2], now, interval)
+	reportMetric(inst.alias, "table_used", m[1], "", m[3], now, interval)
+}
+
+// reportMetric emits a single collected metric. It defaults to writing to
+// collectd via collectdEmit, but is swapped out for a promSink's report
+// method for the duration of a /metrics scrape so that every collector
+// above can feed either output without knowing which mode is active.
+var reportMetric = collectdEmit
+
+// metricsMu serializes /metrics scrapes, since they temporarily repoint
+// the shared reportMetric variable at a request-local promSink.
+var metricsMu sync.Mutex
+
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	sink := &promSink{types: map[string]string{}, samples: map[string][]string{}}
+	reportMetric = sink.report
+	defer func() { reportMetric = collectdEmit }()
+
+	collectInfo(interval)
+	collectStats(interval)
+	collectServersState(interval)
+	collectPools(interval)
+	collectResolvers(interval)
+	collectTables(interval)
+
+	if sink.Empty() {
+		http.Error(w, "no metrics collected, haproxy admin socket(s) may be unreachable", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(sink.Bytes())
+}
+
+// promSink accumulates one scrape's worth of metrics as Prometheus text
+// exposition format, using "proxy" and "sv" labels in place of collectd's
+// dotted TypeInstance names. Samples are buffered per metric name and only
+// assembled into the final output by Bytes, since the exposition format
+// requires every sample for a given metric name to appear together rather
+// than interleaved with other metric families.
+type promSink struct {
+	order   []string
+	types   map[string]string
+	samples map[string][]string
+}
+
+func (s *promSink) report(alias, key, pxname, svname, value string, now time.Time, interval time.Duration) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	m, ok := metricTypes[key]
+	if !ok {
+		return
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		log.Printf("can not convert value for %q: %v\n", key, err)
+		return
+	}
+
+	name := "haproxy_" + m.TypeInstance
+	typ := "gauge"
+	if m.Type == "derive" {
+		typ = "counter"
+	}
+	if _, ok := s.types[name]; !ok {
+		s.types[name] = typ
+		s.order = append(s.order, name)
+	}
+
+	var labels []string
+	if alias != "" {
+		labels = append(labels, fmt.Sprintf("instance=%q", alias))
+	}
+	if pxname = strings.ToLower(strings.TrimSpace(pxname)); pxname != "" {
+		labels = append(labels, fmt.Sprintf("proxy=%q", pxname))
+	}
+	if svname = strings.ToLower(strings.TrimSpace(svname)); svname != "" {
+		labels = append(labels, fmt.Sprintf("sv=%q", svname))
+	}
+	s.samples[name] = append(s.samples[name], fmt.Sprintf("%s{%s} %s\n", name, strings.Join(labels, ","), value))
+}
+
+// Empty reports whether the scrape collected no samples at all, which
+// happens when every instance's admin socket was unreachable; the caller
+// uses this to fail the scrape instead of serving a misleadingly
+// successful empty response.
+func (s *promSink) Empty() bool {
+	return len(s.order) == 0
+}
+
+// Bytes assembles the buffered samples into the final Prometheus text
+// exposition output, one contiguous "# TYPE" header plus sample block per
+// metric name, in the order each metric name was first seen.
+func (s *promSink) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, name := range s.order {
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", name, s.types[name])
+		for _, line := range s.samples[name] {
+			buf.WriteString(line)
+		}
+	}
+	return buf.Bytes()
+}
+
+func collectdEmit(alias, key, pxname, svname, value string, now time.Time, interval time.Duration) {
 	key = strings.ToLower(strings.TrimSpace(key))
 	m, ok := metricTypes[key]
 	if !ok {
@@ -141,10 +532,11 @@ func reportMetric(key, pxname, svname, value string, now time.Time, interval tim
 
 	vl := api.ValueList{
 		Identifier: api.Identifier{
-			Host:         hostname,
-			Plugin:       plugin,
-			Type:         m.Type,
-			TypeInstance: name,
+			Host:           hostname,
+			Plugin:         plugin,
+			PluginInstance: alias,
+			Type:           m.Type,
+			TypeInstance:   name,
 		},
 		Time:     now,
 		Interval: interval,
@@ -171,23 +563,170 @@ func reportMetric(key, pxname, svname, value string, now time.Time, interval tim
 	exec.Putval.Write(vl)
 }
 
-func communicate(command string, buf *bytes.Buffer) error {
-	conn, err := net.Dial("unix", socket)
-	if err != nil {
+// minBackoff and maxBackoff bound the exponential reconnect delay a
+// session applies after a failed dial or a broken connection, e.g. while
+// haproxy is reloading and briefly drops its admin socket.
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// session maintains a persistent, pipelined connection to one haproxy
+// admin socket using haproxy's "prompt" mode, so that the several "show
+// ..." commands issued per interval reuse a single connection instead of
+// dialing fresh for each of them.
+type session struct {
+	addr string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	rd      *bufio.Reader
+	backoff time.Duration
+	retryAt time.Time
+}
+
+var (
+	sessionsMu sync.Mutex
+	sessions   = map[string]*session{}
+)
+
+func sessionFor(addr string) *session {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	s, ok := sessions[addr]
+	if !ok {
+		s = &session{addr: addr}
+		sessions[addr] = s
+	}
+	return s
+}
+
+// communicate sends a single command to the haproxy admin socket at addr,
+// over a persistent connection reused across the interval's other
+// commands, and copies the response into buf. addr is either
+// "unix:/path/to/socket" or "tcp:host:port"; a bare path (no scheme) is
+// dialed as a unix socket for backwards compatibility with the plain
+// -socket flag.
+func communicate(addr, command string, buf *bytes.Buffer) error {
+	return sessionFor(addr).run(command, buf)
+}
+
+// run sends command over the session's connection, (re)connecting first
+// if necessary, and reads the response into buf. haproxy's prompt mode
+// terminates a command's output with a blank line followed by a "> "
+// prompt with no trailing newline; both are consumed here so the
+// connection is left ready for the next command. Some commands (e.g.
+// "show resolvers") can themselves emit blank lines between sections
+// before that final terminator, so a blank line only ends the response
+// if it is immediately followed by the prompt; otherwise it is part of
+// the output and reading continues.
+func (s *session) run(command string, buf *bytes.Buffer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.conn.SetDeadline(time.Now().Add(interval)); err != nil {
+		s.fail()
 		return err
 	}
-	defer conn.Close()
 
-	if _, err := fmt.Fprintln(conn, command); err != nil {
+	if _, err := fmt.Fprintln(s.conn, command); err != nil {
+		s.fail()
 		return err
 	}
+
 	buf.Reset()
-	if _, err := io.Copy(buf, conn); err != nil {
+	for {
+		line, err := s.rd.ReadString('\n')
+		if err != nil {
+			s.fail()
+			return err
+		}
+		if strings.TrimRight(line, "\r\n") == "" {
+			if prompt, err := s.rd.Peek(2); err == nil && string(prompt) == "> " {
+				s.rd.Discard(2)
+				break
+			}
+		}
+		buf.WriteString(line)
+	}
+
+	s.backoff = 0
+	return nil
+}
+
+// connect dials addr and switches the admin socket into prompt mode,
+// unless a previous failure's backoff has not yet elapsed.
+func (s *session) connect() error {
+	if now := time.Now(); now.Before(s.retryAt) {
+		return fmt.Errorf("haproxy %s: reconnect backing off until %s", s.addr, s.retryAt.Format(time.RFC3339))
+	}
+
+	network, address := "unix", s.addr
+	if i := strings.IndexByte(s.addr, ':'); i >= 0 {
+		switch s.addr[:i] {
+		case "unix":
+			address = s.addr[i+1:]
+		case "tcp":
+			network, address = "tcp", s.addr[i+1:]
+		}
+	}
+
+	conn, err := net.DialTimeout(network, address, interval)
+	if err != nil {
+		s.scheduleRetry()
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(interval)); err != nil {
+		conn.Close()
+		s.scheduleRetry()
+		return err
+	}
+
+	rd := bufio.NewReader(conn)
+	if _, err := fmt.Fprintln(conn, "prompt"); err != nil {
+		conn.Close()
+		s.scheduleRetry()
+		return err
+	}
+	if _, err := io.ReadFull(rd, make([]byte, len("> "))); err != nil {
+		conn.Close()
+		s.scheduleRetry()
 		return err
 	}
+
+	s.conn, s.rd = conn, rd
 	return nil
 }
 
+// fail closes a broken connection and schedules a backed-off reconnect
+// attempt; the next run() call will redial lazily.
+func (s *session) fail() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn, s.rd = nil, nil
+	s.scheduleRetry()
+}
+
+func (s *session) scheduleRetry() {
+	switch {
+	case s.backoff == 0:
+		s.backoff = minBackoff
+	case s.backoff < maxBackoff:
+		s.backoff *= 2
+		if s.backoff > maxBackoff {
+			s.backoff = maxBackoff
+		}
+	}
+	s.retryAt = time.Now().Add(s.backoff)
+}
+
 var pool = sync.Pool{
 	New: func() interface{} {
 		return new(bytes.Buffer)
@@ -245,4 +784,34 @@ var metricTypes = map[string]struct {
 	"scur":         {"session_current", "gauge"},
 	"wredis":       {"redistributed", "derive"},
 	"wretr":        {"retries", "derive"},
+	"status":       {"state", "gauge"},
+
+	// show servers state
+	"srv_op_state":     {"server_oper_state", "gauge"},
+	"srv_admin_state":  {"server_admin_state", "gauge"},
+	"srv_uweight":      {"server_weight", "gauge"},
+	"srv_check_status": {"server_check_status", "gauge"},
+
+	// show pools
+	"pool_allocated": {"pool_allocated_bytes", "gauge"},
+	"pool_used":      {"pool_used", "gauge"},
+
+	// show resolvers
+	"resolvers_sent":        {"resolver_sent", "derive"},
+	"resolvers_valid":       {"resolver_valid", "derive"},
+	"resolvers_update":      {"resolver_update", "derive"},
+	"resolvers_cname":       {"resolver_cname", "derive"},
+	"resolvers_cname_error": {"resolver_cname_error", "derive"},
+	"resolvers_any_err":     {"resolver_any_err", "derive"},
+	"resolvers_nx":          {"resolver_nx", "derive"},
+	"resolvers_timeout":     {"resolver_timeout", "derive"},
+	"resolvers_refused":     {"resolver_refused", "derive"},
+	"resolvers_other":       {"resolver_other", "derive"},
+	"resolvers_invalid":     {"resolver_invalid", "derive"},
+	"resolvers_too_big":     {"resolver_too_big", "derive"},
+	"resolvers_outdated":    {"resolver_outdated", "derive"},
+
+	// show table
+	"table_size": {"table_size", "gauge"},
+	"table_used": {"table_used", "gauge"},
 }
@@ -0,0 +1,105 @@
+// Copyright (c) 2015 by Christoph Hack <christoph@tux21b.org>
+// All rights reserved. Distributed under the Simplified BSD License.
+
+package main
+
+import (
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSessionReusesConnection(t *testing.T) {
+	var accepts int32
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&accepts, 1)
+			go serveScriptedConn(conn, map[string]string{"show info": "Name: test\n\n"})
+		}
+	}()
+	addr := "tcp:" + ln.Addr().String()
+
+	for i := 0; i < 3; i++ {
+		buf := newBuffer()
+		if err := communicate(addr, "show info", buf); err != nil {
+			t.Fatalf("communicate #%d: %v", i, err)
+		}
+		freeBuffer(buf)
+	}
+	if got := atomic.LoadInt32(&accepts); got != 1 {
+		t.Errorf("accepted %d connections for 3 commands against one instance, want 1 (session should be reused)", got)
+	}
+}
+
+// TestSessionInteriorBlankLineDoesNotDesyncFraming is a regression test for
+// a "show resolvers" reply containing a blank line before its final
+// terminator: run() must not mistake it for the end of the response, or
+// the next command read off the same persistent connection desyncs.
+func TestSessionInteriorBlankLineDoesNotDesyncFraming(t *testing.T) {
+	addr := newScriptedHAProxy(t, map[string]string{
+		"show resolvers": "Resolvers section mydns\n" +
+			" nameserver dns1:\n" +
+			"  sent:        10\n" +
+			"\n" +
+			"Total:\n" +
+			"  sent:        10\n" +
+			"\n",
+		"show info": "Name: test\n\n",
+	})
+
+	buf := newBuffer()
+	defer freeBuffer(buf)
+	if err := communicate(addr, "show resolvers", buf); err != nil {
+		t.Fatalf("show resolvers: %v", err)
+	}
+
+	buf2 := newBuffer()
+	defer freeBuffer(buf2)
+	if err := communicate(addr, "show info", buf2); err != nil {
+		t.Fatalf("show info after a reply with an interior blank line: %v", err)
+	}
+	if !strings.Contains(buf2.String(), "Name: test") {
+		t.Errorf("show info reply = %q, want it to contain %q (connection desynced by the interior blank line?)", buf2.String(), "Name: test")
+	}
+}
+
+func TestSessionBackoffAfterDialFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := "tcp:" + ln.Addr().String()
+	ln.Close() // nothing listening now, so dials against addr fail
+
+	buf := newBuffer()
+	defer freeBuffer(buf)
+
+	if err := communicate(addr, "show info", buf); err == nil {
+		t.Fatal("communicate: expected a dial error, got nil")
+	}
+
+	s := sessionFor(addr)
+	if s.backoff < minBackoff {
+		t.Errorf("backoff = %v, want at least %v after a failed dial", s.backoff, minBackoff)
+	}
+	if !s.retryAt.After(time.Now()) {
+		t.Error("retryAt should be in the future right after a failed dial")
+	}
+
+	if err := communicate(addr, "show info", buf); err == nil {
+		t.Fatal("communicate: expected the still-backing-off error, got nil")
+	} else if !strings.Contains(err.Error(), "backing off") {
+		t.Errorf("err = %q, want it to mention backing off while retryAt is in the future", err)
+	}
+}